@@ -0,0 +1,193 @@
+package nvme_drv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NETWAYS/check_hp_disk_firmware/nagios"
+	"github.com/NETWAYS/check_hp_disk_firmware/rules"
+	"github.com/NETWAYS/check_hp_disk_firmware/snmp"
+	"github.com/gosnmp/gosnmp"
+)
+
+// maxHours is the perfdata scale ceiling, matching the highest known failure threshold.
+const maxHours = 40000
+
+// cpqNvmeSsdPhyDrvTable is the base OID of the NVMe physical drive table in the CPQIDA MIB.
+const cpqNvmeSsdPhyDrvTable = ".1.3.6.1.4.1.232.3.2.11.2.1"
+
+// Column OIDs within cpqNvmeSsdPhyDrvTable.
+const (
+	cpqNvmeSsdPhyDrvModel  = "8"
+	cpqNvmeSsdPhyDrvSerial = "9"
+	cpqNvmeSsdPhyDrvFWRev  = "10"
+	cpqNvmeSsdPhyDrvStatus = "6"
+	cpqNvmeSsdPhyDrvHours  = "27"
+)
+
+// driveStatusEnum maps the cpqNvmeSsdPhyDrvStatus INTEGER enum to the status strings
+// GetNagiosStatus understands.
+var driveStatusEnum = map[int]string{
+	1: "other",
+	2: "ok",
+	3: "failed",
+	4: "predictiveFailure",
+}
+
+// decodeStatus decodes a cpqNvmeSsdPhyDrvStatus value. gosnmp decodes SNMP INTEGERs as Go
+// int, so the common case is a map lookup; a string/[]byte is passed through for
+// --snmpwalk-file input that already carries a textual status.
+func decodeStatus(value interface{}) string {
+	switch v := value.(type) {
+	case int:
+		if status, ok := driveStatusEnum[v]; ok {
+			return status
+		}
+
+		return fmt.Sprintf("unknown(%d)", v)
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// currentRules holds the affected-model data, the embedded default unless overridden via
+// SetRules (e.g. by --rules-file).
+var currentRules = rules.MustDefault()
+
+// SetRules swaps the active ruleset, e.g. when --rules-file overrides the embedded default.
+func SetRules(r *rules.Registry) {
+	currentRules = r
+}
+
+// snmpTable holds the raw SNMP walk result of a table.
+type snmpTable struct {
+	Values []gosnmp.SnmpPDU
+}
+
+// CpqNvmeSsdPhyDrvTable holds the raw SNMP walk result of cpqNvmeSsdPhyDrvTable.
+type CpqNvmeSsdPhyDrvTable struct {
+	Snmp snmpTable
+}
+
+// GetCpqNvmeSsdPhyDrvTable walks the NVMe physical drive table via SNMP.
+func GetCpqNvmeSsdPhyDrvTable(client gosnmp.Handler) (*CpqNvmeSsdPhyDrvTable, error) {
+	values, err := client.BulkWalkAll(cpqNvmeSsdPhyDrvTable)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CpqNvmeSsdPhyDrvTable{Snmp: snmpTable{Values: values}}, nil
+}
+
+// NvmeDrive represents a single physical drive entry from the cpqNvmeSsdPhyDrvTable.
+type NvmeDrive struct {
+	Id     string
+	Model  string
+	FwRev  string
+	Serial string
+	Status string
+	Hours  int
+
+	// WarnHours/CritHours are power-on-hour perfdata thresholds, set by the caller once the
+	// drive's model is known to be affected. Zero disables the corresponding threshold.
+	WarnHours int
+	CritHours int
+}
+
+// AffectedModel reports whether model is a known-affected NVMe SSD, and the firmware
+// revision that fixes it.
+func AffectedModel(model string) (string, bool) {
+	return currentRules.NvmeFixedFirmware(model)
+}
+
+// GetNvmeDrivesFromTable groups the raw SNMP values of a CpqNvmeSsdPhyDrvTable by drive
+// index and turns each group into an NvmeDrive.
+func GetNvmeDrivesFromTable(table *CpqNvmeSsdPhyDrvTable) ([]*NvmeDrive, error) {
+	drives := make(map[string]*NvmeDrive)
+
+	var order []string
+
+	for _, value := range table.Snmp.Values {
+		id := snmp.GetSubOid(value.Name, cpqNvmeSsdPhyDrvTable)
+		if id == "" {
+			continue
+		}
+
+		parts := strings.SplitN(id, ".", 2)
+		column, index := parts[0], parts[1]
+
+		drive, ok := drives[index]
+		if !ok {
+			drive = &NvmeDrive{Id: index}
+			drives[index] = drive
+			order = append(order, index)
+		}
+
+		switch column {
+		case cpqNvmeSsdPhyDrvModel:
+			drive.Model = strings.TrimSpace(fmt.Sprintf("%s", value.Value))
+		case cpqNvmeSsdPhyDrvSerial:
+			drive.Serial = strings.TrimSpace(fmt.Sprintf("%s", value.Value))
+		case cpqNvmeSsdPhyDrvFWRev:
+			drive.FwRev = strings.TrimSpace(fmt.Sprintf("%s", value.Value))
+		case cpqNvmeSsdPhyDrvStatus:
+			drive.Status = decodeStatus(value.Value)
+		case cpqNvmeSsdPhyDrvHours:
+			hours, ok := value.Value.(int)
+			if ok {
+				drive.Hours = hours
+			}
+		}
+	}
+
+	result := make([]*NvmeDrive, 0, len(order))
+	for _, index := range order {
+		result = append(result, drives[index])
+	}
+
+	return result, nil
+}
+
+// GetNagiosStatus evaluates the drive's status and model/firmware combination against the
+// known NVMe firmware bugs and returns a Nagios status with a human readable description.
+func (d *NvmeDrive) GetNagiosStatus() (int, string) {
+	info := fmt.Sprintf("(%s) model=%s serial=%s firmware=%s hours=%d", d.Id, d.Model, d.Serial, d.FwRev, d.Hours)
+
+	if strings.EqualFold(d.Status, "failed") {
+		return nagios.Critical, info + " - status: failed"
+	}
+
+	if fixedVersion, affected := currentRules.NvmeFixedFirmware(d.Model); affected {
+		if d.FwRev == fixedVersion {
+			return nagios.OK, info + " - firmware update applied"
+		}
+
+		return nagios.Critical, info + " - affected by FW bug, update to " + fixedVersion
+	}
+
+	return nagios.OK, info
+}
+
+// GetPerfData renders the drive's power-on hours and firmware status as Nagios perfdata.
+func (d *NvmeDrive) GetPerfData() string {
+	firmwareOK := 1
+	if fixedVersion, affected := currentRules.NvmeFixedFirmware(d.Model); affected && d.FwRev != fixedVersion {
+		firmwareOK = 0
+	}
+
+	var warn, crit string
+	if d.WarnHours > 0 {
+		warn = strconv.Itoa(d.WarnHours)
+	}
+
+	if d.CritHours > 0 {
+		crit = strconv.Itoa(d.CritHours)
+	}
+
+	return fmt.Sprintf("'hours_%s'=%d;%s;%s;0;%d 'firmware_ok_%s'=%d", d.Id, d.Hours, warn, crit, maxHours, d.Id, firmwareOK)
+}
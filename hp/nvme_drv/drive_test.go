@@ -0,0 +1,51 @@
+package nvme_drv
+
+import (
+	"github.com/NETWAYS/check_hp_disk_firmware/nagios"
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const affectedNvmeDrive = "VO000480KWDUQ"
+const affectedNvmeDriveFixed = "HPD1"
+
+func TestNvmeDrive_GetPerfData(t *testing.T) {
+	drive := &NvmeDrive{
+		Id:        "1",
+		Model:     "OTHERSSD",
+		FwRev:     "HPD0",
+		Hours:     1337,
+		WarnHours: 30000,
+		CritHours: 35000,
+	}
+
+	assert.Equal(t, "'hours_1'=1337;30000;35000;0;40000 'firmware_ok_1'=1", drive.GetPerfData())
+
+	drive.Model = affectedNvmeDrive
+	assert.Equal(t, "'hours_1'=1337;30000;35000;0;40000 'firmware_ok_1'=0", drive.GetPerfData())
+
+	drive.FwRev = affectedNvmeDriveFixed
+	assert.Equal(t, "'hours_1'=1337;30000;35000;0;40000 'firmware_ok_1'=1", drive.GetPerfData())
+}
+
+func TestGetNvmeDrivesFromTable_DecodesStatus(t *testing.T) {
+	table := &CpqNvmeSsdPhyDrvTable{
+		Snmp: snmpTable{
+			Values: []gosnmp.SnmpPDU{
+				{Name: cpqNvmeSsdPhyDrvTable + "." + cpqNvmeSsdPhyDrvModel + ".1", Value: []byte("SOMESSD")},
+				{Name: cpqNvmeSsdPhyDrvTable + "." + cpqNvmeSsdPhyDrvStatus + ".1", Value: 3}, // failed
+			},
+		},
+	}
+
+	drives, err := GetNvmeDrivesFromTable(table)
+	assert.NoError(t, err)
+	assert.Len(t, drives, 1)
+
+	drive := drives[0]
+	assert.Equal(t, "failed", drive.Status)
+
+	status, _ := drive.GetNagiosStatus()
+	assert.Equal(t, nagios.Critical, status)
+}
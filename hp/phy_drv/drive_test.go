@@ -2,6 +2,7 @@ package phy_drv
 
 import (
 	"github.com/NETWAYS/check_hp_disk_firmware/nagios"
+	"github.com/gosnmp/gosnmp"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -46,3 +47,43 @@ func TestPhysicalDrive_GetNagiosStatus(t *testing.T) {
 	assert.Equal(t, nagios.OK, status)
 	assert.Regexp(t, `\(1\.1 \) model=\w+ serial=ABC123 firmware=\w+ hours=1337 - .*applied`, info)
 }
+
+func TestPhysicalDrive_GetPerfData(t *testing.T) {
+	drive := &PhysicalDrive{
+		Id:        "1.1",
+		Model:     "OTHERDRIVE",
+		FwRev:     "HPD1",
+		Hours:     1337,
+		WarnHours: 30000,
+		CritHours: 35000,
+	}
+
+	assert.Equal(t, "'hours_1.1'=1337;30000;35000;0;40000 'firmware_ok_1.1'=1", drive.GetPerfData())
+
+	drive.Model = affectedDrive
+	assert.Equal(t, "'hours_1.1'=1337;30000;35000;0;40000 'firmware_ok_1.1'=0", drive.GetPerfData())
+
+	drive.FwRev = affectedDriveFixed
+	assert.Equal(t, "'hours_1.1'=1337;30000;35000;0;40000 'firmware_ok_1.1'=1", drive.GetPerfData())
+}
+
+func TestGetPhysicalDrivesFromTable_DecodesStatus(t *testing.T) {
+	table := &CpqDaPhyDrvTable{
+		Snmp: snmpTable{
+			Values: []gosnmp.SnmpPDU{
+				{Name: cpqDaPhyDrvTable + "." + cpqDaPhyDrvModel + ".1.1", Value: []byte("OTHERDRIVE")},
+				{Name: cpqDaPhyDrvTable + "." + cpqDaPhyDrvStatus + ".1.1", Value: 3}, // failed
+			},
+		},
+	}
+
+	drives, err := GetPhysicalDrivesFromTable(table)
+	assert.NoError(t, err)
+	assert.Len(t, drives, 1)
+
+	drive := drives[0]
+	assert.Equal(t, "failed", drive.Status)
+
+	status, _ := drive.GetNagiosStatus()
+	assert.Equal(t, nagios.Critical, status)
+}
@@ -0,0 +1,152 @@
+// Package redfish queries an HPE iLO's Redfish API for drive inventory, as an alternative
+// to SNMP for Gen10+ servers that ship with SNMP disabled by default.
+package redfish
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/NETWAYS/check_hp_disk_firmware/hp/nvme_drv"
+	"github.com/NETWAYS/check_hp_disk_firmware/hp/phy_drv"
+)
+
+// systemID is the Redfish ComputerSystem to query; HPE iLO exposes a single system as "1".
+const systemID = "1"
+
+// Client talks to an iLO's Redfish API over HTTPS.
+type Client struct {
+	baseURL  string
+	username string
+	password string
+
+	httpClient *http.Client
+}
+
+// NewClient builds a Redfish client against baseURL, e.g. "https://ilo.example.com". timeout
+// bounds every request the client makes, matching the SNMP path's --timeout flag.
+func NewClient(baseURL, username, password string, insecure bool, timeout time.Duration) *Client {
+	transport := &http.Transport{}
+	if insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // nolint: gosec
+	}
+
+	return &Client{
+		baseURL:    baseURL,
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{Transport: transport, Timeout: timeout},
+	}
+}
+
+type odataCollection struct {
+	Members []struct {
+		OdataID string `json:"@odata.id"`
+	} `json:"Members"`
+}
+
+type storage struct {
+	Drives []struct {
+		OdataID string `json:"@odata.id"`
+	} `json:"Drives"`
+}
+
+type drive struct {
+	Id           string `json:"Id"`
+	Model        string `json:"Model"`
+	SerialNumber string `json:"SerialNumber"`
+	Protocol     string `json:"Protocol"`
+	Revision     string `json:"Revision"`
+	Status       struct {
+		Health string `json:"Health"`
+	} `json:"Status"`
+	Oem struct {
+		Hpe struct {
+			PowerOnHours int `json:"PowerOnHours"`
+		} `json:"Hpe"`
+	} `json:"Oem"`
+}
+
+// GetDrives walks /redfish/v1/Systems/{id}/Storage/*/Drives and returns the SAS/SATA and
+// NVMe drives found, in the same structs the SNMP-based checks consume.
+func (c *Client) GetDrives() ([]*phy_drv.PhysicalDrive, []*nvme_drv.NvmeDrive, error) {
+	var controllers odataCollection
+
+	if err := c.get(fmt.Sprintf("/redfish/v1/Systems/%s/Storage", systemID), &controllers); err != nil {
+		return nil, nil, err
+	}
+
+	var sasDrives []*phy_drv.PhysicalDrive
+
+	var nvmeDrives []*nvme_drv.NvmeDrive
+
+	for _, controller := range controllers.Members {
+		var stor storage
+
+		if err := c.get(controller.OdataID, &stor); err != nil {
+			return nil, nil, err
+		}
+
+		for _, driveRef := range stor.Drives {
+			var d drive
+
+			if err := c.get(driveRef.OdataID, &d); err != nil {
+				return nil, nil, err
+			}
+
+			status := "ok"
+			if d.Status.Health != "" && d.Status.Health != "OK" {
+				status = "failed"
+			}
+
+			if d.Protocol == "NVMe" {
+				nvmeDrives = append(nvmeDrives, &nvme_drv.NvmeDrive{
+					Id:     d.Id,
+					Model:  d.Model,
+					FwRev:  d.Revision,
+					Serial: d.SerialNumber,
+					Status: status,
+					Hours:  d.Oem.Hpe.PowerOnHours,
+				})
+			} else {
+				sasDrives = append(sasDrives, &phy_drv.PhysicalDrive{
+					Id:     d.Id,
+					Model:  d.Model,
+					FwRev:  d.Revision,
+					Serial: d.SerialNumber,
+					Status: status,
+					Hours:  d.Oem.Hpe.PowerOnHours,
+				})
+			}
+		}
+	}
+
+	return sasDrives, nvmeDrives, nil
+}
+
+func (c *Client) get(path string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("redfish GET %s: unexpected status %s", path, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
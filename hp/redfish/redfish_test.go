@@ -0,0 +1,12 @@
+package redfish
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewClient_SetsTimeout(t *testing.T) {
+	client := NewClient("https://ilo.example.com", "user", "pass", false, 5*time.Second)
+	assert.Equal(t, 5*time.Second, client.httpClient.Timeout)
+}
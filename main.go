@@ -3,15 +3,20 @@ package main
 import (
 	"errors"
 	"fmt"
-	"github.com/NETWAYS/check_hp_firmware/hp/cntlr"
-	"github.com/NETWAYS/check_hp_firmware/hp/ilo"
-	"github.com/NETWAYS/check_hp_firmware/hp/phy_drv"
-	"github.com/NETWAYS/check_hp_firmware/nagios"
-	"github.com/NETWAYS/check_hp_firmware/snmp"
+	"github.com/NETWAYS/check_hp_disk_firmware/hp/cntlr"
+	"github.com/NETWAYS/check_hp_disk_firmware/hp/ilo"
+	"github.com/NETWAYS/check_hp_disk_firmware/hp/nvme_drv"
+	"github.com/NETWAYS/check_hp_disk_firmware/hp/phy_drv"
+	"github.com/NETWAYS/check_hp_disk_firmware/hp/redfish"
+	"github.com/NETWAYS/check_hp_disk_firmware/nagios"
+	"github.com/NETWAYS/check_hp_disk_firmware/remediation"
+	"github.com/NETWAYS/check_hp_disk_firmware/rules"
+	"github.com/NETWAYS/check_hp_disk_firmware/snmp"
 	"github.com/gosnmp/gosnmp"
 	log "github.com/sirupsen/logrus"
 	flag "github.com/spf13/pflag"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -43,6 +48,11 @@ plugin does not verify configured logical drives, but we believe you should upda
 The check will raise a CRITICAL when the drive needs to be updated with the note "affected by FW bug", and when
 the drive is patched with "firmware update applied".
 
+**HPE NVMe SSD disks**
+
+  Some HPE NVMe SSD models are affected by the same kind of power-on-hour firmware bug as the SAS/SATA models above.
+  NVMe drives are checked in addition to the SAS/SATA drives unless "--ignore-nvme" is passed.
+
 **HPE Integrated Lights-Out**
   Multiple security vulnerabilities have been identified in Integrated Lights-Out 3 (iLO 3),
   Integrated Lights-Out 4 (iLO 4), and Integrated Lights-Out 5 (iLO 5) firmware. The vulnerabilities could be remotely
@@ -55,6 +65,18 @@ the drive is patched with "firmware update applied".
    - HPE Integrated Lights-Out 4 (iLO 4) firmware v2.75 or later
    - HPE Integrated Lights-Out 5 (iLO 5) firmware v2.18 or later.
 
+**Transports**
+
+  By default the plugin talks to the host via SNMP ("--transport snmp", the default). On Gen10+ servers that ship
+  with SNMP disabled, pass "--transport redfish" together with "--url", "--user" and "--password" to query the same
+  drive inventory via iLO's Redfish API instead. Controller and iLO checks currently require the SNMP transport.
+
+**Remediation**
+
+  Pass "--remediate" together with "--firmware-repo <dir>" to stage firmware fixes for affected drives via the
+  "ilorest" CLI, matching models against a "fwrepo.json" index in that directory. Nothing is touched unless the
+  component is also listed in "--allow-remediate" (e.g. "--allow-remediate=drive"), and "--dry-run" reports what
+  would happen without invoking ilorest.
 
 Please see support documents from HPE:
 * https://support.hpe.com/hpesc/public/docDisplay?docLocale=en_US&docId=emr_na-a00092491en_us
@@ -73,14 +95,39 @@ func main() {
 	flagSet := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 	flagSet.SortFlags = false
 
+	transport := flagSet.String("transport", "snmp", "Transport to use for gathering inventory (snmp/redfish)")
+
 	host := flagSet.StringP("hostname", "H", "localhost", "SNMP host")
 	community := flagSet.StringP("community", "c", "public", "SNMP community")
 	protocol := flagSet.StringP("protocol", "P", "2c", "SNMP protocol")
 	timeout := flagSet.Int64("timeout", 15, "SNMP timeout in seconds")
 
+	securityLevel := flagSet.String("security-level", snmp.SecurityLevelNoAuthNoPriv, "SNMPv3 security level (noAuthNoPriv/authNoPriv/authPriv)")
+	username := flagSet.String("username", "", "SNMPv3 username")
+	authProtocol := flagSet.String("auth-protocol", "SHA", "SNMPv3 authentication protocol (MD5/SHA/SHA224/SHA256/SHA384/SHA512)")
+	authPassword := flagSet.String("auth-password", "", "SNMPv3 authentication password")
+	privProtocol := flagSet.String("priv-protocol", "AES", "SNMPv3 privacy protocol (DES/AES/AES192/AES256)")
+	privPassword := flagSet.String("priv-password", "", "SNMPv3 privacy password")
+	contextName := flagSet.String("context-name", "", "SNMPv3 context name")
+
 	file := flagSet.String("snmpwalk-file", "", "Read output from snmpwalk")
+	rulesFile := flagSet.String("rules-file", "", "Load affected drive/NVMe rules from a YAML/JSON file instead of the embedded defaults")
+
+	redfishURL := flagSet.String("url", "", "Redfish base URL, e.g. https://ilo.example.com (--transport redfish)")
+	redfishUser := flagSet.String("user", "", "Redfish username (--transport redfish)")
+	redfishPassword := flagSet.String("password", "", "Redfish password (--transport redfish)")
+	redfishInsecure := flagSet.Bool("insecure", false, "Skip TLS certificate verification (--transport redfish)")
 
 	ignoreIlo := flagSet.Bool("ignore-ilo-version", false, "Don't check the ILO version")
+	ignoreNvme := flagSet.Bool("ignore-nvme", false, "Don't check NVMe drives")
+
+	driveHoursWarning := flagSet.Int("drive-hours-warning", 30000, "Warning threshold for drive power-on hours (affected models only)")
+	driveHoursCritical := flagSet.Int("drive-hours-critical", 38000, "Critical threshold for drive power-on hours (affected models only)")
+
+	remediate := flagSet.Bool("remediate", false, "Stage firmware fixes for affected components via iLOrest")
+	firmwareRepo := flagSet.String("firmware-repo", "", "Local firmware repository directory indexed by fwrepo.json (--remediate)")
+	dryRun := flagSet.Bool("dry-run", false, "Don't invoke ilorest, just report what --remediate would do")
+	allowRemediate := flagSet.String("allow-remediate", "", "Comma separated components to remediate: drive (--remediate)")
 	_ = flagSet.BoolP("ilo", "I", false, "Checks the version of iLo")
 	_ = flagSet.MarkHidden("ilo")
 
@@ -120,88 +167,163 @@ func main() {
 		defer nagios.CatchPanic()
 	}
 
+	if *rulesFile != "" {
+		registry, err := rules.LoadFile(*rulesFile)
+		if err != nil {
+			nagios.ExitError(err)
+		}
+
+		phy_drv.SetRules(registry)
+		nvme_drv.SetRules(registry)
+	}
+
 	var (
-		client     gosnmp.Handler
-		cntlrTable *cntlr.CpqDaCntlrTable
-		driveTable *phy_drv.CpqDaPhyDrvTable
+		controllers []*cntlr.Controller
+		drives      []*phy_drv.PhysicalDrive
+		nvmeDrives  []*nvme_drv.NvmeDrive
+		iloData     *ilo.Information
 	)
 
-	if *file != "" {
-		client, err = snmp.NewFileHandlerFromFile(*file)
-		if err != nil {
-			nagios.ExitError(err)
+	overall := nagios.Overall{}
+
+	switch *transport {
+	case "redfish":
+		if *redfishURL == "" || *redfishUser == "" {
+			nagios.ExitError(fmt.Errorf("--url and --user are required for --transport redfish"))
 		}
-	} else {
-		client = gosnmp.NewHandler()
-		client.SetTarget(*host)
-		client.SetCommunity(*community)
-		client.SetTimeout(time.Duration(*timeout) * time.Second)
-		client.SetRetries(1)
 
-		version, err := snmp.VersionFromString(*protocol)
+		redfishClient := redfish.NewClient(*redfishURL, *redfishUser, *redfishPassword, *redfishInsecure, time.Duration(*timeout)*time.Second)
+
+		drives, nvmeDrives, err = redfishClient.GetDrives()
 		if err != nil {
 			nagios.ExitError(err)
 		}
+	case "snmp":
+		var (
+			client     gosnmp.Handler
+			cntlrTable *cntlr.CpqDaCntlrTable
+			driveTable *phy_drv.CpqDaPhyDrvTable
+		)
+
+		if *file != "" {
+			client, err = snmp.NewFileHandlerFromFile(*file)
+			if err != nil {
+				nagios.ExitError(err)
+			}
+		} else {
+			client = gosnmp.NewHandler()
+			client.SetTarget(*host)
+			client.SetCommunity(*community)
+			client.SetTimeout(time.Duration(*timeout) * time.Second)
+			client.SetRetries(1)
+
+			version, err := snmp.VersionFromString(*protocol)
+			if err != nil {
+				nagios.ExitError(err)
+			}
+
+			client.SetVersion(version)
+
+			if version == gosnmp.Version3 {
+				v3Config := snmp.V3Config{
+					SecurityLevel: *securityLevel,
+					Username:      *username,
+					AuthProtocol:  *authProtocol,
+					AuthPassword:  *authPassword,
+					PrivProtocol:  *privProtocol,
+					PrivPassword:  *privPassword,
+					ContextName:   *contextName,
+				}
+
+				msgFlags, err := v3Config.MsgFlags()
+				if err != nil {
+					nagios.ExitError(err)
+				}
+
+				securityParameters, err := v3Config.SecurityParameters()
+				if err != nil {
+					nagios.ExitError(err)
+				}
+
+				client.SetSecurityModel(gosnmp.UserSecurityModel)
+				client.SetMsgFlags(msgFlags)
+				client.SetSecurityParameters(securityParameters)
+				client.SetContextName(v3Config.ContextName)
+			}
+		}
 
-		client.SetVersion(version)
-	}
-
-	// Initialize connection
-	if *ipv4 {
-		err = client.ConnectIPv4()
-	} else if *ipv6 {
-		err = client.ConnectIPv6()
-	} else {
-		err = client.Connect()
-	}
-
-	if err != nil {
-		nagios.ExitError(err)
-	}
-
-	defer func() {
-		_ = client.Close()
-	}()
+		// Initialize connection
+		if *ipv4 {
+			err = client.ConnectIPv4()
+		} else if *ipv6 {
+			err = client.ConnectIPv6()
+		} else {
+			err = client.Connect()
+		}
 
-	// Load controller data
-	cntlrTable, err = cntlr.GetCpqDaCntlrTable(client)
-	if err != nil {
-		nagios.ExitError(err)
-	}
+		if err != nil {
+			nagios.ExitError(err)
+		}
 
-	// Load drive data
-	driveTable, err = phy_drv.GetCpqDaPhyDrvTable(client)
-	if err != nil {
-		nagios.ExitError(err)
-	}
+		defer func() {
+			_ = client.Close()
+		}()
 
-	if len(cntlrTable.Snmp.Values) == 0 {
-		nagios.Exit(3, "No HP controller data found!")
-	}
+		// Load controller data
+		cntlrTable, err = cntlr.GetCpqDaCntlrTable(client)
+		if err != nil {
+			nagios.ExitError(err)
+		}
 
-	controllers, err := cntlr.GetControllersFromTable(cntlrTable)
-	if err != nil {
-		nagios.ExitError(err)
-	}
+		// Load drive data
+		driveTable, err = phy_drv.GetCpqDaPhyDrvTable(client)
+		if err != nil {
+			nagios.ExitError(err)
+		}
 
-	if len(driveTable.Snmp.Values) == 0 {
-		nagios.Exit(3, "No HP drive data found!")
-	}
+		if len(cntlrTable.Snmp.Values) == 0 {
+			nagios.Exit(3, "No HP controller data found!")
+		}
 
-	drives, err := phy_drv.GetPhysicalDrivesFromTable(driveTable)
-	if err != nil {
-		nagios.ExitError(err)
-	}
+		controllers, err = cntlr.GetControllersFromTable(cntlrTable)
+		if err != nil {
+			nagios.ExitError(err)
+		}
 
-	overall := nagios.Overall{}
+		if len(driveTable.Snmp.Values) == 0 {
+			nagios.Exit(3, "No HP drive data found!")
+		}
 
-	// check the ILO Version unless set
-	if !*ignoreIlo {
-		iloData, err := ilo.GetIloInformation(client)
+		drives, err = phy_drv.GetPhysicalDrivesFromTable(driveTable)
 		if err != nil {
 			nagios.ExitError(err)
 		}
 
+		// check the ILO Version unless set
+		if !*ignoreIlo {
+			iloData, err = ilo.GetIloInformation(client)
+			if err != nil {
+				nagios.ExitError(err)
+			}
+		}
+
+		// check NVMe drives unless set
+		if !*ignoreNvme {
+			nvmeTable, err := nvme_drv.GetCpqNvmeSsdPhyDrvTable(client)
+			if err != nil {
+				nagios.ExitError(err)
+			}
+
+			nvmeDrives, err = nvme_drv.GetNvmeDrivesFromTable(nvmeTable)
+			if err != nil {
+				nagios.ExitError(err)
+			}
+		}
+	default:
+		nagios.ExitError(fmt.Errorf("unknown transport: %s", *transport))
+	}
+
+	if iloData != nil {
 		overall.Add(iloData.GetNagiosStatus())
 	}
 
@@ -218,13 +340,62 @@ func main() {
 	countDrives := 0
 
 	for _, drive := range drives {
+		if _, affected := phy_drv.AffectedModel(drive.Model); affected {
+			drive.WarnHours = *driveHoursWarning
+			drive.CritHours = *driveHoursCritical
+		}
+
 		driveStatus, desc := drive.GetNagiosStatus()
 
 		overall.Add(driveStatus, desc)
+		overall.AddPerfData(drive.GetPerfData())
 
 		countDrives += 1
 	}
 
+	if !*ignoreNvme {
+		for _, drive := range nvmeDrives {
+			if _, affected := nvme_drv.AffectedModel(drive.Model); affected {
+				drive.WarnHours = *driveHoursWarning
+				drive.CritHours = *driveHoursCritical
+			}
+
+			driveStatus, desc := drive.GetNagiosStatus()
+
+			overall.Add(driveStatus, desc)
+			overall.AddPerfData(drive.GetPerfData())
+
+			countDrives += 1
+		}
+	}
+
+	if *remediate {
+		if *firmwareRepo == "" {
+			nagios.ExitError(fmt.Errorf("--firmware-repo is required for --remediate"))
+		}
+
+		allowed := make(map[string]bool)
+
+		for _, component := range strings.Split(*allowRemediate, ",") {
+			component = strings.TrimSpace(component)
+			if component != "" {
+				allowed[component] = true
+			}
+		}
+
+		runner := remediation.NewIloRestRunner(*firmwareRepo, *dryRun)
+
+		if allowed[string(remediation.ComponentDrive)] {
+			for _, drive := range drives {
+				remediateDrive(runner, &overall, remediation.ComponentDrive, drive.Id, drive.Model, drive.FwRev, phy_drv.AffectedModel)
+			}
+
+			for _, drive := range nvmeDrives {
+				remediateDrive(runner, &overall, remediation.ComponentDrive, drive.Id, drive.Model, drive.FwRev, nvme_drv.AffectedModel)
+			}
+		}
+	}
+
 	var summary string
 
 	status := overall.GetStatus()
@@ -241,3 +412,26 @@ func main() {
 	overall.Summary = summary
 	nagios.Exit(status, overall.GetOutput())
 }
+
+// remediateDrive stages a firmware fix for a drive if affectedModel reports it's affected
+// and not already on the fixed firmware revision, and records the outcome on overall.
+func remediateDrive(runner remediation.Runner, overall *nagios.Overall, component remediation.Component, id, model, fwRev string, affectedModel func(string) (string, bool)) {
+	fixedVersion, affected := affectedModel(model)
+	if !affected || fwRev == fixedVersion {
+		return
+	}
+
+	result, err := runner.Remediate(remediation.Target{
+		Component:  component,
+		Identifier: id,
+		Model:      model,
+		CurrentFW:  fwRev,
+		FixedFW:    fixedVersion,
+	})
+	if err != nil {
+		overall.Add(nagios.Unknown, fmt.Sprintf("remediate %s %s: %s", component, id, err))
+		return
+	}
+
+	overall.Add(result.Status, fmt.Sprintf("remediate %s %s: %s", component, id, result.Message))
+}
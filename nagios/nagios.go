@@ -0,0 +1,95 @@
+package nagios
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Nagios/Icinga plugin exit codes.
+const (
+	OK = iota
+	Warning
+	Critical
+	Unknown
+)
+
+// Overall accumulates the results of several subchecks into a single plugin exit.
+type Overall struct {
+	OKs          int
+	Warnings     int
+	Criticals    int
+	Summary      string
+	Descriptions []string
+	PerfData     []string
+}
+
+// Add records a subcheck's status and optional description lines.
+func (o *Overall) Add(status int, desc ...string) {
+	switch status {
+	case OK:
+		o.OKs++
+	case Warning:
+		o.Warnings++
+	case Critical:
+		o.Criticals++
+	}
+
+	o.Descriptions = append(o.Descriptions, desc...)
+}
+
+// AddPerfData appends a subcheck's Nagios perfdata to the overall output.
+func (o *Overall) AddPerfData(perf string) {
+	if perf != "" {
+		o.PerfData = append(o.PerfData, perf)
+	}
+}
+
+// GetStatus returns Critical if any added subcheck was critical, else Warning if any
+// warned, else OK. Unknown subcheck results are not counted and never change the status.
+func (o *Overall) GetStatus() int {
+	switch {
+	case o.Criticals > 0:
+		return Critical
+	case o.Warnings > 0:
+		return Warning
+	default:
+		return OK
+	}
+}
+
+// GetOutput renders the plugin summary and long output in the usual Nagios format.
+func (o *Overall) GetOutput() string {
+	output := o.Summary
+
+	if len(o.Descriptions) > 0 {
+		output += "\n" + strings.Join(o.Descriptions, "\n")
+	}
+
+	if len(o.PerfData) > 0 {
+		output += "|" + strings.Join(o.PerfData, " ")
+	}
+
+	return output
+}
+
+// Exit prints output and terminates the process with the given Nagios status code.
+func Exit(status int, output string) {
+	fmt.Println(output)
+	os.Exit(status)
+}
+
+// ExitError reports err as an UNKNOWN result.
+func ExitError(err error) {
+	Exit(Unknown, fmt.Sprintf("UNKNOWN - %s", err))
+}
+
+// CatchPanic recovers from a panic and reports it as an UNKNOWN result instead of crashing.
+func CatchPanic() {
+	if r := recover(); r != nil {
+		log.Debugf("recovered panic: %v", r)
+		Exit(Unknown, fmt.Sprintf("UNKNOWN - unexpected error: %v", r))
+	}
+}
@@ -0,0 +1,138 @@
+// Package remediation stages or applies firmware fixes for components found to be
+// affected, via a pluggable Runner.
+package remediation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/NETWAYS/check_hp_disk_firmware/nagios"
+)
+
+// Component identifies which kind of subsystem a Target belongs to.
+type Component string
+
+// ComponentDrive is the only component accepted by --allow-remediate; this package has no
+// data model for controllers or iLO firmware yet.
+const ComponentDrive Component = "drive"
+
+// Target describes one affected component to remediate.
+type Target struct {
+	Component  Component
+	Identifier string
+	Model      string
+	CurrentFW  string
+	FixedFW    string
+}
+
+// Result reports what happened when trying to remediate a Target.
+type Result struct {
+	Target  Target
+	Status  int
+	Message string
+}
+
+// Runner stages or applies a firmware fix for a Target. Implementations do not check the
+// per-component opt-in; the caller only passes Targets whose Component was opted in.
+type Runner interface {
+	Remediate(t Target) (Result, error)
+}
+
+// FWRepoEntry is one entry of a firmware repository's fwrepo.json index.
+type FWRepoEntry struct {
+	Model    string `json:"model"`
+	Target   string `json:"target"`
+	Firmware string `json:"firmware"`
+	File     string `json:"file"`
+}
+
+// IloRestRunner stages firmware fixes via the "ilorest" CLI, matching targets against a
+// local firmware repository directory indexed by fwrepo.json.
+type IloRestRunner struct {
+	RepoDir string
+	DryRun  bool
+
+	runCommand func(name string, args ...string) ([]byte, error)
+
+	// index caches the parsed fwrepo.json for the lifetime of the runner, so a multi-drive
+	// run reads the file once instead of once per Remediate call.
+	index     []FWRepoEntry
+	indexErr  error
+	indexDone bool
+}
+
+// NewIloRestRunner builds a Runner backed by repoDir/fwrepo.json and the "ilorest" binary.
+func NewIloRestRunner(repoDir string, dryRun bool) *IloRestRunner {
+	return &IloRestRunner{
+		RepoDir: repoDir,
+		DryRun:  dryRun,
+		runCommand: func(name string, args ...string) ([]byte, error) {
+			return exec.Command(name, args...).CombinedOutput()
+		},
+	}
+}
+
+// loadIndex reads and parses fwrepo.json once per runner and caches the result (or error)
+// for subsequent calls.
+func (r *IloRestRunner) loadIndex() ([]FWRepoEntry, error) {
+	if r.indexDone {
+		return r.index, r.indexErr
+	}
+
+	r.indexDone = true
+
+	data, err := os.ReadFile(filepath.Join(r.RepoDir, "fwrepo.json"))
+	if err != nil {
+		r.indexErr = fmt.Errorf("reading fwrepo.json: %w", err)
+		return nil, r.indexErr
+	}
+
+	var entries []FWRepoEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		r.indexErr = fmt.Errorf("parsing fwrepo.json: %w", err)
+		return nil, r.indexErr
+	}
+
+	r.index = entries
+
+	return r.index, nil
+}
+
+// Remediate finds the fix firmware for t.Model/t.Component in the repository and stages it
+// via "ilorest flashfwpkg". It reports WARNING (staged, reboot required) on success, and
+// never invokes ilorest when DryRun is set.
+func (r *IloRestRunner) Remediate(t Target) (Result, error) {
+	entries, err := r.loadIndex()
+	if err != nil {
+		return Result{Target: t}, err
+	}
+
+	var match *FWRepoEntry
+
+	for i := range entries {
+		if entries[i].Model == t.Model && entries[i].Target == string(t.Component) {
+			match = &entries[i]
+			break
+		}
+	}
+
+	if match == nil {
+		return Result{Target: t, Status: nagios.Unknown, Message: fmt.Sprintf("no firmware for model %s in %s", t.Model, r.RepoDir)}, nil
+	}
+
+	file := filepath.Join(r.RepoDir, match.File)
+
+	if r.DryRun {
+		return Result{Target: t, Status: nagios.Warning, Message: fmt.Sprintf("dry-run: would flash %s (%s)", file, match.Firmware)}, nil
+	}
+
+	output, err := r.runCommand("ilorest", "flashfwpkg", file)
+	if err != nil {
+		return Result{Target: t, Status: nagios.Critical, Message: fmt.Sprintf("ilorest flashfwpkg failed: %s (%v)", output, err)}, nil
+	}
+
+	return Result{Target: t, Status: nagios.Warning, Message: fmt.Sprintf("staged %s, reboot required", match.Firmware)}, nil
+}
@@ -0,0 +1,85 @@
+package remediation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NETWAYS/check_hp_disk_firmware/nagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFwRepo(t *testing.T, dir string) {
+	t.Helper()
+
+	data := `[{"model":"VO0480JFDGT","target":"drive","firmware":"HPD8","file":"hpd8.fwpkg"}]`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "fwrepo.json"), []byte(data), 0o600))
+}
+
+func TestIloRestRunner_Remediate_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	writeFwRepo(t, dir)
+
+	runner := NewIloRestRunner(dir, true)
+
+	result, err := runner.Remediate(Target{Component: ComponentDrive, Identifier: "1.1", Model: "VO0480JFDGT"})
+	assert.NoError(t, err)
+	assert.Equal(t, nagios.Warning, result.Status)
+	assert.Contains(t, result.Message, "dry-run")
+}
+
+func TestIloRestRunner_Remediate_NoMatch(t *testing.T) {
+	dir := t.TempDir()
+	writeFwRepo(t, dir)
+
+	runner := NewIloRestRunner(dir, false)
+
+	result, err := runner.Remediate(Target{Component: ComponentDrive, Identifier: "1.1", Model: "UNKNOWN-MODEL"})
+	assert.NoError(t, err)
+	assert.Equal(t, nagios.Unknown, result.Status)
+}
+
+func TestIloRestRunner_Remediate_RunsIlorestOncePerTarget(t *testing.T) {
+	dir := t.TempDir()
+	writeFwRepo(t, dir)
+
+	calls := 0
+	runner := NewIloRestRunner(dir, false)
+	runner.runCommand = func(name string, args ...string) ([]byte, error) {
+		calls++
+		return []byte("ok"), nil
+	}
+
+	_, err := runner.Remediate(Target{Component: ComponentDrive, Identifier: "1.1", Model: "VO0480JFDGT"})
+	assert.NoError(t, err)
+
+	_, err = runner.Remediate(Target{Component: ComponentDrive, Identifier: "1.2", Model: "VO0480JFDGT"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestIloRestRunner_LoadIndex_ReadsFileOnce(t *testing.T) {
+	dir := t.TempDir()
+	writeFwRepo(t, dir)
+
+	runner := NewIloRestRunner(dir, true)
+
+	_, err := runner.loadIndex()
+	assert.NoError(t, err)
+
+	// Removing the file proves the second call serves the cached index instead of
+	// re-reading it from disk.
+	assert.NoError(t, os.Remove(filepath.Join(dir, "fwrepo.json")))
+
+	entries, err := runner.loadIndex()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestIloRestRunner_Remediate_MissingRepoFile(t *testing.T) {
+	runner := NewIloRestRunner(t.TempDir(), false)
+
+	_, err := runner.Remediate(Target{Component: ComponentDrive, Identifier: "1.1", Model: "VO0480JFDGT"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,105 @@
+// Package rules loads the affected drive/NVMe models and their fixed firmware versions
+// from a YAML or JSON document, so the plugin's bulletin data can be updated without
+// rebuilding it.
+package rules
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed default_rules.yaml
+var defaultRulesFS embed.FS
+
+// Rule describes a single affected model and the firmware revision that fixes it.
+type Rule struct {
+	Model         string `yaml:"model" json:"model"`
+	FixedFirmware string `yaml:"fixed_firmware" json:"fixed_firmware"`
+	Severity      string `yaml:"severity" json:"severity"`
+}
+
+// Document is the top-level shape of a rules file.
+type Document struct {
+	Drives     []Rule `yaml:"drives" json:"drives"`
+	NvmeDrives []Rule `yaml:"nvme_drives" json:"nvme_drives"`
+}
+
+// Registry answers affected-model lookups for SAS/SATA and NVMe drives, backed by either
+// the embedded default ruleset or a document loaded from a --rules-file. It holds no
+// controller or iLO rules.
+type Registry struct {
+	doc Document
+}
+
+// Default returns a Registry backed by the ruleset compiled into the binary.
+func Default() (*Registry, error) {
+	data, err := defaultRulesFS.ReadFile("default_rules.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	return load(data, yaml.Unmarshal)
+}
+
+// MustDefault is like Default, but panics instead of returning an error. The embedded
+// ruleset is part of the binary, so a parse failure here means a broken build.
+func MustDefault() *Registry {
+	registry, err := Default()
+	if err != nil {
+		panic(err)
+	}
+
+	return registry
+}
+
+// LoadFile builds a Registry from a YAML or JSON rules file, detected by its extension.
+func LoadFile(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rules file: %w", err)
+	}
+
+	unmarshal := yaml.Unmarshal
+	if strings.HasSuffix(path, ".json") {
+		unmarshal = json.Unmarshal
+	}
+
+	return load(data, unmarshal)
+}
+
+func load(data []byte, unmarshal func([]byte, interface{}) error) (*Registry, error) {
+	var doc Document
+
+	if err := unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing rules file: %w", err)
+	}
+
+	return &Registry{doc: doc}, nil
+}
+
+// DriveFixedFirmware reports the firmware revision that fixes model, for SAS/SATA drives.
+func (r *Registry) DriveFixedFirmware(model string) (string, bool) {
+	return matchModel(r.doc.Drives, model)
+}
+
+// NvmeFixedFirmware reports the firmware revision that fixes model, for NVMe drives.
+func (r *Registry) NvmeFixedFirmware(model string) (string, bool) {
+	return matchModel(r.doc.NvmeDrives, model)
+}
+
+func matchModel(ruleSet []Rule, model string) (string, bool) {
+	for _, rule := range ruleSet {
+		matched, err := regexp.MatchString(rule.Model, model)
+		if err == nil && matched {
+			return rule.FixedFirmware, true
+		}
+	}
+
+	return "", false
+}
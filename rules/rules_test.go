@@ -0,0 +1,42 @@
+package rules
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegistry_DriveFixedFirmware(t *testing.T) {
+	registry := MustDefault()
+
+	fixed, affected := registry.DriveFixedFirmware("VO0480JFDGT")
+	assert.True(t, affected)
+	assert.Equal(t, "HPD8", fixed)
+
+	_, affected = registry.DriveFixedFirmware("SOME-UNRELATED-MODEL")
+	assert.False(t, affected)
+}
+
+func TestRegistry_NvmeFixedFirmware(t *testing.T) {
+	registry := MustDefault()
+
+	fixed, affected := registry.NvmeFixedFirmware("VO000480KWDUQ")
+	assert.True(t, affected)
+	assert.Equal(t, "HPD1", fixed)
+
+	_, affected = registry.NvmeFixedFirmware("SOME-UNRELATED-MODEL")
+	assert.False(t, affected)
+}
+
+func TestMatchModel_FirstMatchWins(t *testing.T) {
+	rules := []Rule{
+		{Model: "^FOO.*$", FixedFirmware: "F1"},
+		{Model: "^FOOBAR$", FixedFirmware: "F2"},
+	}
+
+	fixed, affected := matchModel(rules, "FOOBAR")
+	assert.True(t, affected)
+	assert.Equal(t, "F1", fixed)
+
+	_, affected = matchModel(rules, "BAZ")
+	assert.False(t, affected)
+}
@@ -55,19 +55,108 @@ func GetSubOid(oid string, baseOid string) string {
 	return oid[l+1:]
 }
 
-func SetVersion(client *gosnmp.GoSNMP, version string) error {
+// VersionFromString maps a --protocol value to a gosnmp.SnmpVersion.
+func VersionFromString(version string) (gosnmp.SnmpVersion, error) {
 	switch version {
 	case "1":
-		client.Version = gosnmp.Version1
+		return gosnmp.Version1, nil
 	case "2", "2c":
-		client.Version = gosnmp.Version2c
+		return gosnmp.Version2c, nil
 	case "3":
-		client.Version = gosnmp.Version3
-		// TODO: support v3?
-		return fmt.Errorf("SNMPv3 config not implemented")
+		return gosnmp.Version3, nil
 	default:
-		return fmt.Errorf("unknown SNMP version: %s", version)
+		return 0, fmt.Errorf("unknown SNMP version: %s", version)
+	}
+}
+
+// Recognized values for the --security-level flag.
+const (
+	SecurityLevelNoAuthNoPriv = "noAuthNoPriv"
+	SecurityLevelAuthNoPriv   = "authNoPriv"
+	SecurityLevelAuthPriv     = "authPriv"
+)
+
+// authProtocols maps --auth-protocol values to the gosnmp constant.
+var authProtocols = map[string]gosnmp.SnmpV3AuthProtocol{
+	"MD5":    gosnmp.MD5,
+	"SHA":    gosnmp.SHA,
+	"SHA224": gosnmp.SHA224,
+	"SHA256": gosnmp.SHA256,
+	"SHA384": gosnmp.SHA384,
+	"SHA512": gosnmp.SHA512,
+}
+
+// privProtocols maps --priv-protocol values to the gosnmp constant.
+var privProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+	"DES":    gosnmp.DES,
+	"AES":    gosnmp.AES,
+	"AES192": gosnmp.AES192,
+	"AES256": gosnmp.AES256,
+}
+
+// V3Config carries the CLI flags needed to build SNMPv3 USM security parameters.
+type V3Config struct {
+	SecurityLevel string
+	Username      string
+	AuthProtocol  string
+	AuthPassword  string
+	PrivProtocol  string
+	PrivPassword  string
+	ContextName   string
+}
+
+// MsgFlags translates the configured security level into a gosnmp.SnmpV3MsgFlags.
+func (c V3Config) MsgFlags() (gosnmp.SnmpV3MsgFlags, error) {
+	switch c.SecurityLevel {
+	case SecurityLevelNoAuthNoPriv, "":
+		return gosnmp.NoAuthNoPriv, nil
+	case SecurityLevelAuthNoPriv:
+		return gosnmp.AuthNoPriv, nil
+	case SecurityLevelAuthPriv:
+		return gosnmp.AuthPriv, nil
+	default:
+		return 0, fmt.Errorf("unknown SNMP security level: %s", c.SecurityLevel)
+	}
+}
+
+// SecurityParameters validates the v3 config and builds the USM security parameters.
+// authPriv requires both an auth and a priv password; authNoPriv requires an auth password.
+func (c V3Config) SecurityParameters() (*gosnmp.UsmSecurityParameters, error) {
+	if c.Username == "" {
+		return nil, fmt.Errorf("--username is required for SNMPv3")
+	}
+
+	params := &gosnmp.UsmSecurityParameters{
+		UserName: c.Username,
+	}
+
+	if c.SecurityLevel == SecurityLevelAuthNoPriv || c.SecurityLevel == SecurityLevelAuthPriv {
+		authProtocol, ok := authProtocols[c.AuthProtocol]
+		if !ok {
+			return nil, fmt.Errorf("unknown SNMP auth protocol: %s", c.AuthProtocol)
+		}
+
+		if c.AuthPassword == "" {
+			return nil, fmt.Errorf("--auth-password is required for security level %s", c.SecurityLevel)
+		}
+
+		params.AuthenticationProtocol = authProtocol
+		params.AuthenticationPassphrase = c.AuthPassword
+	}
+
+	if c.SecurityLevel == SecurityLevelAuthPriv {
+		privProtocol, ok := privProtocols[c.PrivProtocol]
+		if !ok {
+			return nil, fmt.Errorf("unknown SNMP priv protocol: %s", c.PrivProtocol)
+		}
+
+		if c.PrivPassword == "" {
+			return nil, fmt.Errorf("--priv-password is required for security level authPriv")
+		}
+
+		params.PrivacyProtocol = privProtocol
+		params.PrivacyPassphrase = c.PrivPassword
 	}
 
-	return nil
+	return params, nil
 }
@@ -0,0 +1,56 @@
+package snmp
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV3Config_SecurityParameters(t *testing.T) {
+	// noAuthNoPriv only needs a username
+	cfg := V3Config{SecurityLevel: SecurityLevelNoAuthNoPriv, Username: "monitoring"}
+	params, err := cfg.SecurityParameters()
+	assert.NoError(t, err)
+	assert.Equal(t, "monitoring", params.UserName)
+
+	// missing username is always rejected
+	_, err = V3Config{SecurityLevel: SecurityLevelNoAuthNoPriv}.SecurityParameters()
+	assert.Error(t, err)
+
+	// authNoPriv requires an auth password and a known auth protocol
+	_, err = V3Config{SecurityLevel: SecurityLevelAuthNoPriv, Username: "monitoring", AuthProtocol: "SHA"}.SecurityParameters()
+	assert.Error(t, err)
+
+	_, err = V3Config{SecurityLevel: SecurityLevelAuthNoPriv, Username: "monitoring", AuthProtocol: "bogus", AuthPassword: "secret"}.SecurityParameters()
+	assert.Error(t, err)
+
+	params, err = V3Config{SecurityLevel: SecurityLevelAuthNoPriv, Username: "monitoring", AuthProtocol: "SHA", AuthPassword: "secret"}.SecurityParameters()
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", params.AuthenticationPassphrase)
+
+	// authPriv additionally requires a priv password and a known priv protocol
+	_, err = V3Config{
+		SecurityLevel: SecurityLevelAuthPriv, Username: "monitoring",
+		AuthProtocol: "SHA", AuthPassword: "secret",
+		PrivProtocol: "AES",
+	}.SecurityParameters()
+	assert.Error(t, err)
+
+	params, err = V3Config{
+		SecurityLevel: SecurityLevelAuthPriv, Username: "monitoring",
+		AuthProtocol: "SHA", AuthPassword: "secret",
+		PrivProtocol: "AES", PrivPassword: "privsecret",
+	}.SecurityParameters()
+	assert.NoError(t, err)
+	assert.Equal(t, "privsecret", params.PrivacyPassphrase)
+}
+
+func TestV3Config_MsgFlags(t *testing.T) {
+	flags, err := V3Config{SecurityLevel: SecurityLevelAuthPriv}.MsgFlags()
+	assert.NoError(t, err)
+	assert.Equal(t, gosnmp.AuthPriv, flags)
+
+	_, err = V3Config{SecurityLevel: "bogus"}.MsgFlags()
+	assert.Error(t, err)
+}